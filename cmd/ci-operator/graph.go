@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/openshift/ci-tools/pkg/api"
+)
+
+// graphOptions holds the flags that drive which steps ci-operator
+// schedules, as opposed to building and running the full config. It is
+// meant to be embedded into ci-operator's existing options alongside
+// the flags that drive config loading and step construction: call
+// bindGraphOptions with the same FlagSet ci-operator's own options bind
+// to, then pass the steps that flow already builds into resolveGraph
+// and runGraph in place of (or ahead of) its usual run path.
+type graphOptions struct {
+	// reverseFrom, when set, makes ci-operator run only the named step
+	// and the steps that (transitively) depend on it instead of the
+	// named step's own dependencies -- the mirror image of --target, useful
+	// for impact analysis ("if I change this image, what re-runs?").
+	reverseFrom string
+	// printGraph, when set to "dot", makes ci-operator print the step
+	// graph as Graphviz DOT (pipe into "dot -Tsvg" to visualize)
+	// instead of running it.
+	printGraph string
+}
+
+func bindGraphOptions(fs *flag.FlagSet) *graphOptions {
+	o := &graphOptions{}
+	fs.StringVar(&o.reverseFrom, "reverse-from", "", "Run only the named step and the steps that transitively depend on it, the mirror image of --target.")
+	fs.StringVar(&o.printGraph, "print-graph", "", `If set to "dot", print the step graph as Graphviz DOT instead of running it.`)
+	return o
+}
+
+// resolveGraph builds the roots ci-operator should operate on: the
+// reverse closure from --reverse-from if it was set, otherwise the
+// full forward graph, validated to reject cycles before anything is
+// scheduled.
+func resolveGraph(steps []api.Step, o *graphOptions) ([]*api.StepNode, error) {
+	if o.reverseFrom != "" {
+		return api.BuildReversePartialGraph(steps, []string{o.reverseFrom})
+	}
+	return api.BuildGraphChecked(steps)
+}
+
+// runGraph either prints roots as DOT to out when --print-graph=dot
+// was given, or schedules roots into levels and runs every step.
+func runGraph(ctx context.Context, roots []*api.StepNode, o *graphOptions, out io.Writer) error {
+	if o.printGraph == "dot" {
+		dot, err := api.RenderDOT(roots, api.RenderOptions{})
+		if err != nil {
+			return fmt.Errorf("could not render step graph: %w", err)
+		}
+		_, err = out.Write(dot)
+		return err
+	}
+
+	levels, err := api.ScheduleLevels(roots)
+	if err != nil {
+		return fmt.Errorf("could not schedule steps: %w", err)
+	}
+	for _, level := range levels {
+		for _, step := range level {
+			if err := step.Run(ctx); err != nil {
+				return fmt.Errorf("step %s failed: %w", step.Name(), err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/openshift/ci-tools/pkg/api"
+)
+
+type fakeStep struct {
+	name     string
+	requires []api.StepLink
+	creates  []api.StepLink
+	ran      bool
+}
+
+func (s *fakeStep) Inputs() (api.InputDefinition, error) { return nil, nil }
+func (s *fakeStep) Run(ctx context.Context) error        { s.ran = true; return nil }
+func (s *fakeStep) Name() string                         { return s.name }
+func (s *fakeStep) Description() string                  { return "" }
+func (s *fakeStep) Requires() []api.StepLink             { return s.requires }
+func (s *fakeStep) Creates() []api.StepLink              { return s.creates }
+func (s *fakeStep) Provides() api.ParameterMap           { return nil }
+
+func TestResolveGraphReverseFrom(t *testing.T) {
+	src := &fakeStep{name: "src", creates: []api.StepLink{api.InternalImageLink("src")}}
+	test := &fakeStep{name: "test", requires: []api.StepLink{api.InternalImageLink("src")}}
+	steps := []api.Step{src, test}
+
+	roots, err := resolveGraph(steps, &graphOptions{reverseFrom: "src"})
+	if err != nil {
+		t.Fatalf("resolveGraph: %v", err)
+	}
+	if err := runGraph(context.Background(), roots, &graphOptions{}, &bytes.Buffer{}); err != nil {
+		t.Fatalf("runGraph: %v", err)
+	}
+	if !src.ran || !test.ran {
+		t.Errorf("expected both src and test to run, got src.ran=%v test.ran=%v", src.ran, test.ran)
+	}
+}
+
+func TestResolveGraphForward(t *testing.T) {
+	src := &fakeStep{name: "src", creates: []api.StepLink{api.InternalImageLink("src")}}
+	test := &fakeStep{name: "test", requires: []api.StepLink{api.InternalImageLink("src")}}
+	steps := []api.Step{src, test}
+
+	roots, err := resolveGraph(steps, &graphOptions{})
+	if err != nil {
+		t.Fatalf("resolveGraph: %v", err)
+	}
+	if len(roots) != 1 || roots[0].Step.Name() != "src" {
+		t.Errorf("expected src as the sole root, got %v", roots)
+	}
+}
+
+func TestRunGraphPrintsDOT(t *testing.T) {
+	src := &fakeStep{name: "src", creates: []api.StepLink{api.InternalImageLink("src")}}
+	test := &fakeStep{name: "test", requires: []api.StepLink{api.InternalImageLink("src")}}
+	roots := api.BuildGraph([]api.Step{src, test})
+
+	var out bytes.Buffer
+	if err := runGraph(context.Background(), roots, &graphOptions{printGraph: "dot"}, &out); err != nil {
+		t.Fatalf("runGraph: %v", err)
+	}
+	if src.ran || test.ran {
+		t.Error("expected --print-graph=dot to print instead of running steps")
+	}
+	if !strings.HasPrefix(out.String(), "digraph step_graph {") {
+		t.Errorf("expected a DOT document, got %q", out.String())
+	}
+	if !strings.Contains(out.String(), `"src" -> "test"`) {
+		t.Errorf("expected the src -> test edge in the DOT output, got %q", out.String())
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"github.com/google/go-cmp/cmp"
+	"sort"
 	"strings"
 )
 
@@ -216,16 +217,43 @@ type StepNode struct {
 // BuildGraph returns a graph or graphs that include
 // all steps given.
 func BuildGraph(steps []Step) []*StepNode {
-	var allNodes []*StepNode
+	_, roots := buildGraphNodes(steps)
+	return roots
+}
+
+// BuildGraphChecked behaves like BuildGraph but additionally validates
+// that the Requires()/Creates() relation between the given steps is
+// acyclic, returning a *CycleError if it is not. Callers that cannot
+// tolerate a non-terminating traversal downstream should prefer this
+// over BuildGraph.
+func BuildGraphChecked(steps []Step) ([]*StepNode, error) {
+	all, roots := buildGraphNodes(steps)
+	if err := detectCycle(all); err != nil {
+		return nil, err
+	}
+	return roots, nil
+}
+
+// ValidateStepGraph returns an error describing the first cycle found
+// in the Requires()/Creates() relation between the given steps, or nil
+// if the relation is acyclic.
+func ValidateStepGraph(steps []Step) error {
+	all, _ := buildGraphNodes(steps)
+	return detectCycle(all)
+}
+
+// buildGraphNodes wires up a StepNode for every step, linking parent to
+// child for every Requires()/Creates() match, and returns both the full
+// node set and the subset of nodes with no parent (the roots).
+func buildGraphNodes(steps []Step) (all, roots []*StepNode) {
 	for _, step := range steps {
 		node := StepNode{Step: step, Children: []*StepNode{}}
-		allNodes = append(allNodes, &node)
+		all = append(all, &node)
 	}
 
-	var roots []*StepNode
-	for _, node := range allNodes {
+	for _, node := range all {
 		isRoot := true
-		for _, other := range allNodes {
+		for _, other := range all {
 			for _, nodeRequires := range node.Step.Requires() {
 				for _, otherCreates := range other.Step.Creates() {
 					if nodeRequires.SatisfiedBy(otherCreates) {
@@ -240,7 +268,109 @@ func BuildGraph(steps []Step) []*StepNode {
 		}
 	}
 
-	return roots
+	return all, roots
+}
+
+// CycleError is returned when the Requires()/Creates() relation between
+// steps contains a cycle, which would otherwise cause consumers of the
+// graph to traverse it forever.
+type CycleError struct {
+	// Cycle lists the step names that make up the cycle, in traversal
+	// order, with the first step repeated at the end (e.g. ["A", "B",
+	// "C", "A"]) to make the loop explicit.
+	Cycle []string
+	// Link is the StepLink that closed the loop: the link that the
+	// first step in Cycle requires and that is satisfied by something
+	// the last step in Cycle (the one immediately before the repeated
+	// first step) creates.
+	Link StepLink
+}
+
+func (e *CycleError) Error() string {
+	return fmt.Sprintf("cycle detected in step graph: %s", strings.Join(e.Cycle, " -> "))
+}
+
+// color marks the DFS state of a node during cycle detection: white
+// nodes are unvisited, gray nodes are on the current DFS path, and
+// black nodes have been fully explored.
+type color int
+
+const (
+	white color = iota
+	gray
+	black
+)
+
+// detectCycle runs a DFS with white/gray/black coloring over the given
+// nodes and returns a *CycleError for the first back-edge it finds.
+func detectCycle(nodes []*StepNode) error {
+	colors := make(map[*StepNode]color, len(nodes))
+
+	var path []*StepNode
+	var visit func(node *StepNode) error
+	visit = func(node *StepNode) error {
+		colors[node] = gray
+		path = append(path, node)
+		for _, child := range node.Children {
+			switch colors[child] {
+			case gray:
+				return cycleErrorFor(path, child)
+			case black:
+				continue
+			default:
+				if err := visit(child); err != nil {
+					return err
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		colors[node] = black
+		return nil
+	}
+
+	for _, node := range nodes {
+		if colors[node] == white {
+			if err := visit(node); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// cycleErrorFor builds a CycleError from the current DFS path and the
+// gray node that the path looped back onto.
+func cycleErrorFor(path []*StepNode, closing *StepNode) *CycleError {
+	start := 0
+	for i, node := range path {
+		if node == closing {
+			start = i
+			break
+		}
+	}
+	cycle := path[start:]
+
+	names := make([]string, 0, len(cycle)+1)
+	for _, node := range cycle {
+		names = append(names, node.Step.Name())
+	}
+	names = append(names, closing.Step.Name())
+
+	last := cycle[len(cycle)-1]
+	var link StepLink
+	for _, requires := range closing.Step.Requires() {
+		for _, creates := range last.Step.Creates() {
+			if requires.SatisfiedBy(creates) {
+				link = requires
+				break
+			}
+		}
+		if link != nil {
+			break
+		}
+	}
+
+	return &CycleError{Cycle: names, Link: link}
 }
 
 // BuildPartialGraph returns a graph or graphs that include
@@ -296,6 +426,62 @@ func BuildPartialGraph(steps []Step, names []string) ([]*StepNode, error) {
 	return BuildGraph(targeted), nil
 }
 
+// BuildReversePartialGraph returns a graph or graphs that include
+// only the named steps and the steps that (transitively) depend on
+// them, i.e. the steps that require a link created by one of the named
+// steps or by anything downstream of them. This is the mirror image of
+// BuildPartialGraph, which walks upstream from the named steps.
+func BuildReversePartialGraph(steps []Step, names []string) ([]*StepNode, error) {
+	if len(names) == 0 {
+		return BuildGraph(steps), nil
+	}
+
+	var provided []StepLink
+	candidates := make([]bool, len(steps))
+	var allNames []string
+	for i, step := range steps {
+		allNames = append(allNames, step.Name())
+		for j, name := range names {
+			if name != step.Name() {
+				continue
+			}
+			candidates[i] = true
+			provided = append(provided, step.Creates()...)
+			names = append(names[:j], names[j+1:]...)
+			break
+		}
+	}
+	if len(names) > 0 {
+		return nil, fmt.Errorf("the following names were not found in the config or were duplicates: %s (from %s)", strings.Join(names, ", "), strings.Join(allNames, ", "))
+	}
+
+	// identify all other steps that require any links provided by the current set
+	for {
+		added := 0
+		for i, step := range steps {
+			if candidates[i] {
+				continue
+			}
+			if HasAnyLinks(step.Requires(), provided) {
+				added++
+				candidates[i] = true
+				provided = append(provided, step.Creates()...)
+			}
+		}
+		if added == 0 {
+			break
+		}
+	}
+
+	var targeted []Step
+	for i, candidate := range candidates {
+		if candidate {
+			targeted = append(targeted, steps[i])
+		}
+	}
+	return BuildGraph(targeted), nil
+}
+
 func addToNode(parent, child *StepNode) bool {
 	for _, s := range parent.Children {
 		if s == child {
@@ -332,6 +518,168 @@ func HasAllLinks(needles, haystack []StepLink) bool {
 	return true
 }
 
+// requirementsSatisfied reports whether every one of requires is
+// satisfied by some link in satisfied, checking require.SatisfiedBy(s)
+// per requirement rather than HasAllLinks's satisfied.SatisfiedBy(require)
+// -- the two agree only when links match symmetrically (e.g. tag==tag),
+// but a tag-level requirement satisfied by a stream-level create (as
+// with a release import) only matches in this direction.
+func requirementsSatisfied(requires, satisfied []StepLink) bool {
+	for _, require := range requires {
+		if !HasAnyLinks([]StepLink{require}, satisfied) {
+			return false
+		}
+	}
+	return true
+}
+
+// collectNodes returns every node reachable from roots, with
+// duplicates removed.
+func collectNodes(roots []*StepNode) []*StepNode {
+	seen := make(map[*StepNode]bool)
+	var all []*StepNode
+	var visit func(node *StepNode)
+	visit = func(node *StepNode) {
+		if seen[node] {
+			return
+		}
+		seen[node] = true
+		all = append(all, node)
+		for _, child := range node.Children {
+			visit(child)
+		}
+	}
+	for _, root := range roots {
+		visit(root)
+	}
+	return all
+}
+
+// ScheduleLevels groups the steps reachable from roots into waves
+// suitable for concurrent dispatch: level 0 holds every step whose
+// requirements are satisfied by nothing else in the graph, and level
+// k+1 holds every step all of whose requirements are satisfied by the
+// steps in levels 0..k, i.e. requires.SatisfiedBy(creates) for some
+// create in a prior level -- the same direction BuildReversePartialGraph
+// walks the graph in. Dispatching a level at a time with e.g. a single
+// errgroup replaces having to re-check each step's readiness from its
+// own goroutine. Each level is sorted by Step.Name() so the ordering,
+// and any artifact derived from it, is stable across runs.
+//
+// ScheduleLevels returns an error if a cycle leaves one or more steps
+// perpetually unready; callers that already run ValidateStepGraph
+// beforehand will never see this, but ScheduleLevels does not assume
+// that has happened and will not silently drop steps from the result.
+func ScheduleLevels(roots []*StepNode) ([][]Step, error) {
+	nodes := collectNodes(roots)
+
+	var allCreates []StepLink
+	for _, node := range nodes {
+		allCreates = append(allCreates, node.Step.Creates()...)
+	}
+
+	// a step's internal requirements are the ones some other step in
+	// the graph actually creates; anything else is an external
+	// dependency (e.g. a base image) that is never going to show up
+	// in a level and must not block scheduling.
+	internal := make(map[*StepNode][]StepLink, len(nodes))
+	for _, node := range nodes {
+		for _, requires := range node.Step.Requires() {
+			if HasAnyLinks([]StepLink{requires}, allCreates) {
+				internal[node] = append(internal[node], requires)
+			}
+		}
+	}
+
+	var levels [][]Step
+	var satisfied []StepLink
+	remaining := nodes
+	for len(remaining) > 0 {
+		var ready, rest []*StepNode
+		for _, node := range remaining {
+			if requirementsSatisfied(internal[node], satisfied) {
+				ready = append(ready, node)
+			} else {
+				rest = append(rest, node)
+			}
+		}
+		if len(ready) == 0 {
+			// a cycle among the remaining steps means none of them
+			// will ever become ready; report it rather than silently
+			// dropping them from the returned levels.
+			names := make([]string, 0, len(rest))
+			for _, node := range rest {
+				names = append(names, node.Step.Name())
+			}
+			sort.Strings(names)
+			return nil, fmt.Errorf("steps could not be scheduled, a cycle exists among: %s", strings.Join(names, ", "))
+		}
+		sort.Slice(ready, func(i, j int) bool { return ready[i].Step.Name() < ready[j].Step.Name() })
+		steps := make([]Step, 0, len(ready))
+		for _, node := range ready {
+			steps = append(steps, node.Step)
+			satisfied = append(satisfied, node.Step.Creates()...)
+		}
+		levels = append(levels, steps)
+		remaining = rest
+	}
+	return levels, nil
+}
+
+// CriticalPath computes, for every step reachable from roots, the
+// length of the longest chain of steps that must complete before it
+// can run. Roots have depth 0; a step's depth is one more than the
+// deepest of its parents'. Schedulers with a limited concurrency
+// budget can use this to prioritize the steps on the longest chain,
+// since delaying any of them delays the whole pipeline.
+//
+// A cycle among the nodes cannot make a chain infinitely long, so
+// CriticalPath tolerates one: a parent found still in progress in a
+// node's own ancestry is left out of that node's depth instead of
+// being recursed into again.
+func CriticalPath(roots []*StepNode) map[Step]int {
+	nodes := collectNodes(roots)
+
+	parents := make(map[*StepNode][]*StepNode, len(nodes))
+	for _, node := range nodes {
+		for _, child := range node.Children {
+			parents[child] = append(parents[child], node)
+		}
+	}
+
+	depth := make(map[*StepNode]int, len(nodes))
+	visiting := make(map[*StepNode]bool, len(nodes))
+	var compute func(node *StepNode) int
+	compute = func(node *StepNode) int {
+		if d, ok := depth[node]; ok {
+			return d
+		}
+		if visiting[node] {
+			return -1
+		}
+		visiting[node] = true
+		max := -1
+		for _, parent := range parents[node] {
+			if d := compute(parent); d > max {
+				max = d
+			}
+		}
+		delete(visiting, node)
+		d := max + 1
+		depth[node] = d
+		return d
+	}
+	for _, node := range nodes {
+		compute(node)
+	}
+
+	result := make(map[Step]int, len(nodes))
+	for _, node := range nodes {
+		result[node.Step] = depth[node]
+	}
+	return result
+}
+
 type CIOperatorStepGraph []CIOperatorStepWithDependencies
 
 type CIOperatorStepWithDependencies struct {
@@ -0,0 +1,55 @@
+package api
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestValidateStepGraphAcyclic(t *testing.T) {
+	if err := ValidateStepGraph(linearChain()); err != nil {
+		t.Errorf("expected no error for an acyclic graph, got %v", err)
+	}
+}
+
+func TestValidateStepGraphCycle(t *testing.T) {
+	// a -> b -> c -> a
+	a := &fakeStep{name: "a", requires: []StepLink{InternalImageLink("c")}, creates: []StepLink{InternalImageLink("a")}}
+	b := &fakeStep{name: "b", requires: []StepLink{InternalImageLink("a")}, creates: []StepLink{InternalImageLink("b")}}
+	c := &fakeStep{name: "c", requires: []StepLink{InternalImageLink("b")}, creates: []StepLink{InternalImageLink("c")}}
+
+	err := ValidateStepGraph([]Step{a, b, c})
+	if err == nil {
+		t.Fatal("expected a cycle error, got none")
+	}
+	cycleErr, ok := err.(*CycleError)
+	if !ok {
+		t.Fatalf("expected a *CycleError, got %T: %v", err, err)
+	}
+	if len(cycleErr.Cycle) < 2 || cycleErr.Cycle[0] != cycleErr.Cycle[len(cycleErr.Cycle)-1] {
+		t.Errorf("Cycle = %v, want the first step repeated at the end", cycleErr.Cycle)
+	}
+	if cycleErr.Link == nil {
+		t.Error("expected Link to identify the StepLink that closed the loop, got nil")
+	}
+
+	if _, err := BuildGraphChecked([]Step{a, b, c}); err == nil {
+		t.Error("expected BuildGraphChecked to surface the same cycle error")
+	}
+}
+
+func TestBuildGraphCheckedAcyclic(t *testing.T) {
+	roots, err := BuildGraphChecked(linearChain())
+	if err != nil {
+		t.Fatalf("BuildGraphChecked: %v", err)
+	}
+	var got []string
+	for _, node := range collectNodes(roots) {
+		got = append(got, node.Step.Name())
+	}
+	sort.Strings(got)
+	want := stepNames(linearChain())
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("BuildGraphChecked nodes = %v, want %v", got, want)
+	}
+}
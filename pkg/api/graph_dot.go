@@ -0,0 +1,146 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// RenderOptions controls optional features of RenderDOT's output.
+type RenderOptions struct {
+	// Title, if set, is used as the rendered graph's label.
+	Title string
+	// Ran, if set, identifies the steps known to have executed, e.g.
+	// the step names present in a ci-operator-step-graph.json
+	// artifact from a previous run. Steps present are coloured as
+	// having run; steps absent are coloured as skipped.
+	Ran map[string]bool
+}
+
+// RenderDOT walks the graph rooted at roots and emits a Graphviz DOT
+// document: one node per Step labelled with its Name() and
+// Description(), and one edge per parent/child relationship labelled
+// with the kind of StepLink that satisfied the dependency. Steps are
+// grouped into subgraphs by what they produce (pipeline image builds,
+// release imports, or plain tests) and, when opts.Ran is supplied,
+// coloured by whether the step is known to have run.
+func RenderDOT(roots []*StepNode, opts RenderOptions) ([]byte, error) {
+	nodes := collectNodes(roots)
+
+	var buf bytes.Buffer
+	buf.WriteString("digraph step_graph {\n")
+	if opts.Title != "" {
+		fmt.Fprintf(&buf, "  label=%q;\n", opts.Title)
+	}
+
+	clusters := map[string][]*StepNode{}
+	for _, node := range nodes {
+		cluster := clusterFor(node.Step)
+		clusters[cluster] = append(clusters[cluster], node)
+	}
+
+	for _, cluster := range []string{"pipeline_builds", "release_imports", "tests"} {
+		members := clusters[cluster]
+		if len(members) == 0 {
+			continue
+		}
+		sort.Slice(members, func(i, j int) bool { return members[i].Step.Name() < members[j].Step.Name() })
+		fmt.Fprintf(&buf, "  subgraph cluster_%s {\n", cluster)
+		fmt.Fprintf(&buf, "    label=%q;\n", strings.ReplaceAll(cluster, "_", " "))
+		for _, node := range members {
+			writeDOTNode(&buf, node, opts)
+		}
+		buf.WriteString("  }\n")
+	}
+
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Step.Name() < nodes[j].Step.Name() })
+	for _, node := range nodes {
+		children := append([]*StepNode{}, node.Children...)
+		sort.Slice(children, func(i, j int) bool { return children[i].Step.Name() < children[j].Step.Name() })
+		for _, child := range children {
+			fmt.Fprintf(&buf, "  %q -> %q [label=%q];\n", node.Step.Name(), child.Step.Name(), linkKind(linkForEdge(node, child)))
+		}
+	}
+
+	buf.WriteString("}\n")
+	return buf.Bytes(), nil
+}
+
+func writeDOTNode(buf *bytes.Buffer, node *StepNode, opts RenderOptions) {
+	label := node.Step.Name()
+	if desc := node.Step.Description(); desc != "" {
+		label = fmt.Sprintf("%s\\n%s", label, desc)
+	}
+	attrs := fmt.Sprintf("label=%q", label)
+	if opts.Ran != nil {
+		if opts.Ran[node.Step.Name()] {
+			attrs += `, style="filled", fillcolor="lightgreen"`
+		} else {
+			attrs += `, style="filled", fillcolor="lightgray"`
+		}
+	}
+	fmt.Fprintf(buf, "    %q [%s];\n", node.Step.Name(), attrs)
+}
+
+// clusterFor buckets a step by what it produces: a step that creates a
+// tag in the pipeline image stream is a build, a step that creates a
+// tag or stream in any other image stream (a stable(-foo)? stream or
+// the release payload stream) is a release import,
+// and anything else (most commonly a test with no Creates() at all)
+// falls into the catch-all "tests" cluster.
+func clusterFor(step Step) string {
+	for _, link := range step.Creates() {
+		switch l := link.(type) {
+		case *internalImageStreamTagLink:
+			if l.name == PipelineImageStream {
+				return "pipeline_builds"
+			}
+			return "release_imports"
+		case *internalImageStreamLink:
+			return "release_imports"
+		}
+	}
+	return "tests"
+}
+
+// linkForEdge returns the StepLink that parent creates to satisfy one
+// of child's requirements, i.e. the link responsible for the parent ->
+// child edge.
+func linkForEdge(parent, child *StepNode) StepLink {
+	for _, requires := range child.Step.Requires() {
+		for _, creates := range parent.Step.Creates() {
+			if requires.SatisfiedBy(creates) {
+				return creates
+			}
+		}
+	}
+	return nil
+}
+
+// linkKind names the kind of dependency a StepLink represents, for use
+// as a DOT edge label.
+func linkKind(link StepLink) string {
+	switch l := link.(type) {
+	case *internalImageStreamTagLink:
+		if l.name == ReleaseImageStream || IsReleaseStream(l.name) {
+			return "release-payload"
+		}
+		return "internal-image-stream"
+	case *internalImageStreamLink:
+		if IsReleaseStream(l.name) {
+			return "release-payload"
+		}
+		return "internal-image-stream"
+	case *externalImageLink:
+		return "external-image"
+	case *imagesReadyLink:
+		return "images-ready"
+	case *rpmRepoLink:
+		return "rpm-repo"
+	case allStepsLink:
+		return "all-steps"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,52 @@
+package api
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderDOT(t *testing.T) {
+	roots := BuildGraph(linearChain())
+
+	dot, err := RenderDOT(roots, RenderOptions{})
+	if err != nil {
+		t.Fatalf("RenderDOT: %v", err)
+	}
+
+	want := `digraph step_graph {
+  subgraph cluster_pipeline_builds {
+    label="pipeline builds";
+    "mid" [label="mid"];
+    "src" [label="src"];
+  }
+  subgraph cluster_tests {
+    label="tests";
+    "test1" [label="test1"];
+    "test2" [label="test2"];
+  }
+  "mid" -> "test1" [label="internal-image-stream"];
+  "mid" -> "test2" [label="internal-image-stream"];
+  "src" -> "mid" [label="internal-image-stream"];
+}
+`
+	if got := string(dot); got != want {
+		t.Errorf("RenderDOT() =\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestRenderDOTColoursByRan(t *testing.T) {
+	roots := BuildGraph(linearChain())
+
+	dot, err := RenderDOT(roots, RenderOptions{Ran: map[string]bool{"src": true}})
+	if err != nil {
+		t.Fatalf("RenderDOT: %v", err)
+	}
+
+	got := string(dot)
+	if !strings.Contains(got, `"src" [label="src", style="filled", fillcolor="lightgreen"];`) {
+		t.Errorf("expected src to be coloured as ran, got:\n%s", got)
+	}
+	if !strings.Contains(got, `"mid" [label="mid", style="filled", fillcolor="lightgray"];`) {
+		t.Errorf("expected mid to be coloured as not ran, got:\n%s", got)
+	}
+}
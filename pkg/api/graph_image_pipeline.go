@@ -0,0 +1,144 @@
+package api
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ImagePipeline describes how a single internal image tag came to be
+// and where it is used, so that tooling doesn't need to re-derive the
+// answer from Requires()/Creates() every time it needs to answer "how
+// did this image get built and where is it used".
+type ImagePipeline struct {
+	// DestinationRef is the image stream tag this pipeline describes,
+	// formatted as "stream:tag".
+	DestinationRef string
+	// Producer is the step that creates the tag.
+	Producer Step
+	// Base is the step whose output Producer consumes as its own
+	// starting point, i.e. the step that produces the tag Producer
+	// names as its input base image. Nil if Producer does not build
+	// on another pipeline image.
+	Base Step
+	// Source is the step at the root of the chain of pipeline images
+	// that Producer was built on top of: following Base as far back
+	// as it goes. Equal to Producer itself when Producer has no Base.
+	Source Step
+	// Consumers are the steps that require the tag, as determined by
+	// matching Requires() against both the tag's own link and the link
+	// LinkForImage constructs for it.
+	Consumers []Step
+	// ReleaseImport is the step that imports the release populating
+	// the tag's stream, set only when the tag lives in a
+	// stable(-foo)? release stream.
+	ReleaseImport Step
+}
+
+// AnalyzeImagePipelines walks the graph reachable from roots and
+// yields one ImagePipeline per internalImageStreamTagLink created by
+// some step in it.
+func AnalyzeImagePipelines(roots []*StepNode) []ImagePipeline {
+	nodes := collectNodes(roots)
+
+	var pipelines []ImagePipeline
+	for _, node := range nodes {
+		for _, link := range node.Step.Creates() {
+			tagLink, ok := link.(*internalImageStreamTagLink)
+			if !ok {
+				continue
+			}
+
+			pipeline := ImagePipeline{
+				DestinationRef: fmt.Sprintf("%s:%s", tagLink.name, tagLink.tag),
+				Producer:       node.Step,
+			}
+
+			if base := immediateBase(nodes, node); base != nil {
+				pipeline.Base = base.Step
+			}
+			pipeline.Source = rootSource(nodes, node).Step
+
+			consumedAs := LinkForImage(tagLink.name, tagLink.tag)
+			for _, other := range nodes {
+				for _, requires := range other.Step.Requires() {
+					if requires.SatisfiedBy(tagLink) || (consumedAs != nil && requires.SatisfiedBy(consumedAs)) {
+						pipeline.Consumers = append(pipeline.Consumers, other.Step)
+						break
+					}
+				}
+			}
+			sort.Slice(pipeline.Consumers, func(i, j int) bool {
+				return pipeline.Consumers[i].Name() < pipeline.Consumers[j].Name()
+			})
+
+			if IsReleaseStream(tagLink.name) {
+				pipeline.ReleaseImport = releaseImportOf(nodes, tagLink.name)
+			}
+
+			pipelines = append(pipelines, pipeline)
+		}
+	}
+
+	sort.Slice(pipelines, func(i, j int) bool {
+		if pipelines[i].Producer.Name() != pipelines[j].Producer.Name() {
+			return pipelines[i].Producer.Name() < pipelines[j].Producer.Name()
+		}
+		return pipelines[i].DestinationRef < pipelines[j].DestinationRef
+	})
+	return pipelines
+}
+
+// immediateBase returns the step, if any, that produces the pipeline
+// image tag node's own step takes as its input base image.
+func immediateBase(nodes []*StepNode, node *StepNode) *StepNode {
+	for _, requires := range node.Step.Requires() {
+		tagRequires, ok := requires.(*internalImageStreamTagLink)
+		if !ok || tagRequires.name != PipelineImageStream {
+			continue
+		}
+		if producer := producerOf(nodes, requires); producer != nil {
+			return producer
+		}
+	}
+	return nil
+}
+
+// rootSource follows immediateBase as far back as it goes and returns
+// the step at the root of that chain. A base-image cycle is not an
+// error here -- AnalyzeImagePipelines makes no assumption that
+// ValidateStepGraph has run -- so the walk stops and returns the
+// furthest node reached once it would revisit one already seen,
+// instead of looping forever.
+func rootSource(nodes []*StepNode, node *StepNode) *StepNode {
+	seen := map[*StepNode]bool{node: true}
+	for {
+		base := immediateBase(nodes, node)
+		if base == nil || seen[base] {
+			return node
+		}
+		seen[base] = true
+		node = base
+	}
+}
+
+// producerOf returns the node whose Creates() satisfies requires.
+func producerOf(nodes []*StepNode, requires StepLink) *StepNode {
+	for _, other := range nodes {
+		for _, creates := range other.Step.Creates() {
+			if requires.SatisfiedBy(creates) {
+				return other
+			}
+		}
+	}
+	return nil
+}
+
+// releaseImportOf returns the step that imports the release populating
+// stream, i.e. the step that creates the stream-level link for it.
+func releaseImportOf(nodes []*StepNode, stream string) Step {
+	streamLink := ReleaseImagesLink(ReleaseNameFrom(stream))
+	if producer := producerOf(nodes, streamLink); producer != nil {
+		return producer.Step
+	}
+	return nil
+}
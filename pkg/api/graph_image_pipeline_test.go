@@ -0,0 +1,93 @@
+package api
+
+import (
+	"reflect"
+	"testing"
+)
+
+func findPipeline(t *testing.T, pipelines []ImagePipeline, destinationRef string) ImagePipeline {
+	t.Helper()
+	for _, pipeline := range pipelines {
+		if pipeline.DestinationRef == destinationRef {
+			return pipeline
+		}
+	}
+	t.Fatalf("no pipeline found for %s among %d pipelines", destinationRef, len(pipelines))
+	return ImagePipeline{}
+}
+
+func TestAnalyzeImagePipelinesChain(t *testing.T) {
+	roots := BuildGraph(linearChain())
+	pipelines := AnalyzeImagePipelines(roots)
+
+	src := findPipeline(t, pipelines, "pipeline:src")
+	if src.Producer.Name() != "src" {
+		t.Errorf("src pipeline Producer = %s, want src", src.Producer.Name())
+	}
+	if src.Base != nil {
+		t.Errorf("src pipeline Base = %v, want nil", src.Base)
+	}
+	if src.Source == nil || src.Source.Name() != "src" {
+		t.Errorf("src pipeline Source = %v, want src", src.Source)
+	}
+	if got := stepNames(src.Consumers); !reflect.DeepEqual(got, []string{"mid"}) {
+		t.Errorf("src pipeline Consumers = %v, want [mid]", got)
+	}
+
+	mid := findPipeline(t, pipelines, "pipeline:mid")
+	if mid.Producer.Name() != "mid" {
+		t.Errorf("mid pipeline Producer = %s, want mid", mid.Producer.Name())
+	}
+	if mid.Base == nil || mid.Base.Name() != "src" {
+		t.Errorf("mid pipeline Base = %v, want src", mid.Base)
+	}
+	if mid.Source == nil || mid.Source.Name() != "src" {
+		t.Errorf("mid pipeline Source = %v, want src", mid.Source)
+	}
+	if got := stepNames(mid.Consumers); !reflect.DeepEqual(got, []string{"test1", "test2"}) {
+		t.Errorf("mid pipeline Consumers = %v, want [test1 test2]", got)
+	}
+}
+
+func TestAnalyzeImagePipelinesBaseCycleDoesNotHang(t *testing.T) {
+	// a and b each claim the other as their pipeline base image; this
+	// must terminate rather than loop forever following immediateBase.
+	a := &fakeStep{
+		name:     "a",
+		requires: []StepLink{InternalImageLink("b")},
+		creates:  []StepLink{InternalImageLink("a")},
+	}
+	b := &fakeStep{
+		name:     "b",
+		requires: []StepLink{InternalImageLink("a")},
+		creates:  []StepLink{InternalImageLink("b")},
+	}
+	root := &fakeStep{name: "root", creates: []StepLink{InternalImageLink("b")}}
+
+	roots := BuildGraph([]Step{root, a, b})
+	pipelines := AnalyzeImagePipelines(roots)
+
+	if len(pipelines) != 3 {
+		t.Fatalf("got %d pipelines, want 3", len(pipelines))
+	}
+}
+
+func TestAnalyzeImagePipelinesReleaseImport(t *testing.T) {
+	importStep := &fakeStep{name: "import", creates: []StepLink{ReleaseImagesLink(LatestReleaseName)}}
+	tagProducer := &fakeStep{name: "produce-installer", creates: []StepLink{ReleaseImageTagLink(LatestReleaseName, "installer")}}
+	consumer := &fakeStep{name: "consume-installer", requires: []StepLink{ReleaseImageTagLink(LatestReleaseName, "installer")}}
+
+	roots := BuildGraph([]Step{importStep, tagProducer, consumer})
+	pipelines := AnalyzeImagePipelines(roots)
+
+	installer := findPipeline(t, pipelines, "stable:installer")
+	if installer.Producer.Name() != "produce-installer" {
+		t.Errorf("installer pipeline Producer = %s, want produce-installer", installer.Producer.Name())
+	}
+	if installer.ReleaseImport == nil || installer.ReleaseImport.Name() != "import" {
+		t.Errorf("installer pipeline ReleaseImport = %v, want import", installer.ReleaseImport)
+	}
+	if got := stepNames(installer.Consumers); !reflect.DeepEqual(got, []string{"consume-installer"}) {
+		t.Errorf("installer pipeline Consumers = %v, want [consume-installer]", got)
+	}
+}
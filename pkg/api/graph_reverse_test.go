@@ -0,0 +1,87 @@
+package api
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// fakeStep is a minimal Step used across this package's tests.
+type fakeStep struct {
+	name        string
+	description string
+	requires    []StepLink
+	creates     []StepLink
+	provides    ParameterMap
+	inputs      InputDefinition
+	inputsErr   error
+	runErr      error
+}
+
+func (s *fakeStep) Inputs() (InputDefinition, error) { return s.inputs, s.inputsErr }
+func (s *fakeStep) Run(ctx context.Context) error    { return s.runErr }
+func (s *fakeStep) Name() string                     { return s.name }
+func (s *fakeStep) Description() string              { return s.description }
+func (s *fakeStep) Requires() []StepLink             { return s.requires }
+func (s *fakeStep) Creates() []StepLink              { return s.creates }
+func (s *fakeStep) Provides() ParameterMap           { return s.provides }
+
+func stepNames(steps []Step) []string {
+	var names []string
+	for _, step := range steps {
+		names = append(names, step.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+// linear builds a chain src -> mid -> test1, test2, where mid depends
+// on src and test1/test2 both depend on mid.
+func linearChain() []Step {
+	src := &fakeStep{name: "src", creates: []StepLink{InternalImageLink("src")}}
+	mid := &fakeStep{
+		name:     "mid",
+		requires: []StepLink{InternalImageLink("src")},
+		creates:  []StepLink{InternalImageLink("mid")},
+	}
+	test1 := &fakeStep{name: "test1", requires: []StepLink{InternalImageLink("mid")}}
+	test2 := &fakeStep{name: "test2", requires: []StepLink{InternalImageLink("mid")}}
+	return []Step{src, mid, test1, test2}
+}
+
+func TestBuildReversePartialGraph(t *testing.T) {
+	steps := linearChain()
+
+	roots, err := BuildReversePartialGraph(steps, []string{"src"})
+	if err != nil {
+		t.Fatalf("BuildReversePartialGraph: %v", err)
+	}
+	var got []string
+	for _, node := range collectNodes(roots) {
+		got = append(got, node.Step.Name())
+	}
+	sort.Strings(got)
+	want := []string{"mid", "src", "test1", "test2"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("reverse closure from src = %v, want %v", got, want)
+	}
+
+	roots, err = BuildReversePartialGraph(steps, []string{"mid"})
+	if err != nil {
+		t.Fatalf("BuildReversePartialGraph: %v", err)
+	}
+	got = nil
+	for _, node := range collectNodes(roots) {
+		got = append(got, node.Step.Name())
+	}
+	sort.Strings(got)
+	want = []string{"mid", "test1", "test2"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("reverse closure from mid = %v, want %v", got, want)
+	}
+
+	if _, err := BuildReversePartialGraph(steps, []string{"nonexistent"}); err == nil {
+		t.Error("expected an error for an unknown step name, got none")
+	}
+}
@@ -0,0 +1,106 @@
+package api
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestScheduleLevels(t *testing.T) {
+	roots := BuildGraph(linearChain())
+
+	levels, err := ScheduleLevels(roots)
+	if err != nil {
+		t.Fatalf("ScheduleLevels: %v", err)
+	}
+
+	var got [][]string
+	for _, level := range levels {
+		got = append(got, stepNames(level))
+	}
+	want := [][]string{{"src"}, {"mid"}, {"test1", "test2"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("levels = %v, want %v", got, want)
+	}
+}
+
+func TestScheduleLevelsCycle(t *testing.T) {
+	// root is reachable and schedulable on its own, but a and b each
+	// require something only the other creates, so neither can ever
+	// become ready.
+	root := &fakeStep{name: "root", creates: []StepLink{InternalImageLink("root-tag")}}
+	a := &fakeStep{
+		name:     "a",
+		requires: []StepLink{InternalImageLink("root-tag"), InternalImageLink("b-tag")},
+		creates:  []StepLink{InternalImageLink("a-tag")},
+	}
+	b := &fakeStep{
+		name:     "b",
+		requires: []StepLink{InternalImageLink("a-tag")},
+		creates:  []StepLink{InternalImageLink("b-tag")},
+	}
+
+	roots := BuildGraph([]Step{root, a, b})
+	if _, err := ScheduleLevels(roots); err == nil {
+		t.Error("expected an error for a graph with an unschedulable cycle, got none")
+	}
+}
+
+func TestScheduleLevelsStreamSatisfiesTag(t *testing.T) {
+	// importStep creates the whole release stream; consumer only
+	// requires one tag in it. The requirement is satisfied by the
+	// stream-level create even though the two links are not equal, so
+	// this must not be reported as an unresolvable cycle.
+	importStep := &fakeStep{name: "import", creates: []StepLink{ReleaseImagesLink(LatestReleaseName)}}
+	consumer := &fakeStep{name: "consume-installer", requires: []StepLink{ReleaseImageTagLink(LatestReleaseName, "installer")}}
+
+	roots := BuildGraph([]Step{importStep, consumer})
+
+	levels, err := ScheduleLevels(roots)
+	if err != nil {
+		t.Fatalf("ScheduleLevels: %v", err)
+	}
+
+	var got [][]string
+	for _, level := range levels {
+		got = append(got, stepNames(level))
+	}
+	want := [][]string{{"import"}, {"consume-installer"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("levels = %v, want %v", got, want)
+	}
+}
+
+func TestCriticalPath(t *testing.T) {
+	roots := BuildGraph(linearChain())
+
+	depths := CriticalPath(roots)
+	want := map[string]int{"src": 0, "mid": 1, "test1": 2, "test2": 2}
+	for name, wantDepth := range want {
+		var found bool
+		for step, depth := range depths {
+			if step.Name() != name {
+				continue
+			}
+			found = true
+			if depth != wantDepth {
+				t.Errorf("depth[%s] = %d, want %d", name, depth, wantDepth)
+			}
+		}
+		if !found {
+			t.Errorf("no depth recorded for step %s", name)
+		}
+	}
+}
+
+func TestCriticalPathDoesNotRecurseForever(t *testing.T) {
+	a := &fakeStep{name: "a", requires: []StepLink{InternalImageLink("b")}, creates: []StepLink{InternalImageLink("a")}}
+	b := &fakeStep{name: "b", requires: []StepLink{InternalImageLink("a")}, creates: []StepLink{InternalImageLink("b")}}
+	root := &fakeStep{name: "root", creates: []StepLink{InternalImageLink("b")}}
+
+	roots := BuildGraph([]Step{root, a, b})
+	// this must return rather than stack-overflow on the a<->b cycle
+	depths := CriticalPath(roots)
+	if len(depths) != 3 {
+		t.Errorf("got depths for %d steps, want 3", len(depths))
+	}
+}
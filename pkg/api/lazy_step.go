@@ -0,0 +1,123 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// LazyStep wraps a Step so that its Inputs() result and each parameter
+// thunk returned by its Provides() are computed at most once,
+// regardless of how many times, or by how many callers, they are
+// invoked. The underlying work is often a Kubernetes API round-trip or
+// a git resolution, so pipeline definitions can declare it freely
+// without paying for it until (and unless) a downstream step actually
+// references it.
+type LazyStep struct {
+	Step
+
+	inputsOnce sync.Once
+	inputs     InputDefinition
+	inputsErr  error
+
+	providesOnce sync.Once
+	provides     ParameterMap
+}
+
+// NewLazyStep wraps step so its Inputs() and Provides() thunks are
+// memoised.
+func NewLazyStep(step Step) *LazyStep {
+	return &LazyStep{Step: step}
+}
+
+// Inputs computes the wrapped step's inputs on first call and returns
+// the cached result thereafter.
+func (s *LazyStep) Inputs() (InputDefinition, error) {
+	s.inputsOnce.Do(func() {
+		s.inputs, s.inputsErr = s.Step.Inputs()
+	})
+	return s.inputs, s.inputsErr
+}
+
+// Provides returns the wrapped step's parameters with each thunk
+// wrapped so it runs at most once, no matter how many callers invoke
+// it or how many times.
+func (s *LazyStep) Provides() ParameterMap {
+	s.providesOnce.Do(func() {
+		underlying := s.Step.Provides()
+		memoised := make(ParameterMap, len(underlying))
+		for name, thunk := range underlying {
+			memoised[name] = memoize(thunk)
+		}
+		s.provides = memoised
+	})
+	return s.provides
+}
+
+// memoize wraps thunk in a sync.Once-backed cache so it runs at most
+// once no matter how many callers invoke the returned function.
+func memoize(thunk func() (string, error)) func() (string, error) {
+	var once sync.Once
+	var value string
+	var err error
+	return func() (string, error) {
+		once.Do(func() {
+			value, err = thunk()
+		})
+		return value, err
+	}
+}
+
+// resolveConcurrency bounds how many parameter thunks Resolve
+// evaluates at once, so a large ParameterMap doesn't open unbounded
+// concurrent Kubernetes API calls or git resolutions.
+const resolveConcurrency = 10
+
+// Resolve evaluates every thunk in the map concurrently, bounded to
+// resolveConcurrency in-flight evaluations at a time, and returns the
+// resolved values together with an aggregate of every error
+// encountered.
+func (m ParameterMap) Resolve(ctx context.Context) (map[string]string, error) {
+	type result struct {
+		name  string
+		value string
+		err   error
+	}
+
+	results := make(chan result, len(m))
+	sem := make(chan struct{}, resolveConcurrency)
+	var wg sync.WaitGroup
+	for name, thunk := range m {
+		wg.Add(1)
+		go func(name string, thunk func() (string, error)) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				results <- result{name: name, err: ctx.Err()}
+				return
+			}
+			value, err := thunk()
+			results <- result{name: name, value: value, err: err}
+		}(name, thunk)
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	values := make(map[string]string, len(m))
+	var errs []error
+	for r := range results {
+		if r.err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", r.name, r.err))
+			continue
+		}
+		values[r.name] = r.value
+	}
+	return values, utilerrors.NewAggregate(errs)
+}
@@ -0,0 +1,94 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+)
+
+func TestLazyStepMemoisesInputs(t *testing.T) {
+	var calls int32
+	counting := &countingInputsStep{fakeStep: fakeStep{name: "lazy"}, calls: &calls}
+	lazy := NewLazyStep(counting)
+
+	for i := 0; i < 3; i++ {
+		if _, err := lazy.Inputs(); err != nil {
+			t.Fatalf("Inputs: %v", err)
+		}
+	}
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Errorf("underlying Inputs() called %d times, want 1", got)
+	}
+}
+
+type countingInputsStep struct {
+	fakeStep
+	calls *int32
+}
+
+func (s *countingInputsStep) Inputs() (InputDefinition, error) {
+	atomic.AddInt32(s.calls, 1)
+	return InputDefinition{"dep"}, nil
+}
+
+func TestLazyStepMemoisesProvides(t *testing.T) {
+	var calls int32
+	underlying := &fakeStep{
+		name: "lazy",
+		provides: ParameterMap{
+			"PARAM": func() (string, error) {
+				atomic.AddInt32(&calls, 1)
+				return "value", nil
+			},
+		},
+	}
+	lazy := NewLazyStep(underlying)
+
+	for i := 0; i < 3; i++ {
+		provides := lazy.Provides()
+		value, err := provides["PARAM"]()
+		if err != nil {
+			t.Fatalf("PARAM: %v", err)
+		}
+		if value != "value" {
+			t.Errorf("PARAM = %q, want %q", value, "value")
+		}
+	}
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Errorf("underlying thunk called %d times, want 1", got)
+	}
+}
+
+func TestParameterMapResolve(t *testing.T) {
+	params := ParameterMap{
+		"A": func() (string, error) { return "a-value", nil },
+		"B": func() (string, error) { return "b-value", nil },
+		"C": func() (string, error) { return "", fmt.Errorf("broken") },
+	}
+
+	values, err := params.Resolve(context.Background())
+	if err == nil {
+		t.Fatal("expected an aggregate error for the failing thunk, got none")
+	}
+	if values["A"] != "a-value" || values["B"] != "b-value" {
+		t.Errorf("values = %v, want A=a-value and B=b-value", values)
+	}
+	if _, ok := values["C"]; ok {
+		t.Errorf("values = %v, want no entry for the failing thunk C", values)
+	}
+}
+
+func TestParameterMapResolveAllSucceed(t *testing.T) {
+	params := ParameterMap{
+		"A": func() (string, error) { return "a-value", nil },
+	}
+
+	values, err := params.Resolve(context.Background())
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if values["A"] != "a-value" {
+		t.Errorf("values = %v, want A=a-value", values)
+	}
+}